@@ -2,36 +2,154 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"math/rand"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/robfig/cron/v3"
+	"golang.org/x/net/proxy"
 	"gopkg.in/yaml.v2"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api"
 	surflineapi "github.com/mdecourcy/go-surfline-api/pkg/surflineapi"
+
+	"github.com/macdecourcy/surfline2influxdb/pkg/httpapi"
+	"github.com/macdecourcy/surfline2influxdb/pkg/nws"
+	"github.com/macdecourcy/surfline2influxdb/pkg/sink"
+	"github.com/macdecourcy/surfline2influxdb/pkg/sink/influxsink"
+	"github.com/macdecourcy/surfline2influxdb/pkg/sink/mqttsink"
+	"github.com/macdecourcy/surfline2influxdb/pkg/spotresolver"
+	"github.com/macdecourcy/surfline2influxdb/pkg/stats"
 )
 
+type SpotConfig struct {
+	ID       string `yaml:"id"`
+	Schedule string `yaml:"schedule"`
+}
+
 type Config struct {
 	InfluxDB struct {
 		Url    string `yaml:"url"`
 		Org    string `yaml:"org"`
 		Bucket string `yaml:"bucket"`
 	} `yaml:"influxdb"`
-	Spots map[string]string `yaml:"spots"`
+	Mqtt struct {
+		Broker      string `yaml:"broker"`
+		ClientId    string `yaml:"clientId"`
+		Username    string `yaml:"username"`
+		Password    string `yaml:"password"`
+		TopicPrefix string `yaml:"topicPrefix"`
+		Qos         byte   `yaml:"qos"`
+		KeepAlive   int    `yaml:"keepAliveSeconds"`
+	} `yaml:"mqtt"`
+	Http struct {
+		Listen                     string `yaml:"listen"`
+		DialTimeoutSeconds         int    `yaml:"dialTimeoutSeconds"`
+		DialKeepAliveSeconds       int    `yaml:"dialKeepAliveSeconds"`
+		MaxIdleConns               int    `yaml:"maxIdleConns"`
+		IdleConnTimeoutSeconds     int    `yaml:"idleConnTimeoutSeconds"`
+		TLSHandshakeTimeoutSeconds int    `yaml:"tlsHandshakeTimeoutSeconds"`
+		RequestTimeoutSeconds      int    `yaml:"requestTimeoutSeconds"`
+	} `yaml:"http"`
+	// Concurrency is the number of forecast fetches allowed to run at
+	// once, across all spots. Defaults to runtime.NumCPU() when unset.
+	Concurrency int          `yaml:"concurrency"`
+	Spots       []SpotConfig `yaml:"spots"`
+}
+
+// forecastTypes are the four forecasts fetched for every spot. Each is
+// dispatched as its own job, so a broken endpoint for one type doesn't
+// block the others for the same spot.
+var forecastTypes = []string{"wind", "wave", "tide", "rating"}
+
+// forecastJob is one unit of work for the worker pool: fetch and write a
+// single forecast type for a single spot.
+type forecastJob struct {
+	spotID       string
+	forecastType string
 }
 
 const maxRetries = 3
 const retryDelay = 5 * time.Second
 
+// maxStartupJitter caps the random delay inserted before each scheduled
+// fetch so that spots sharing a cron expression don't all hit Surfline
+// in the same instant.
+const maxStartupJitter = 30 * time.Second
+
+// Defaults for the Surfline/NWS HTTP client's transport, used whenever the
+// config doesn't set an explicit value under the http: block.
+const (
+	defaultDialTimeout         = 10 * time.Second
+	defaultDialKeepAlive       = 30 * time.Second
+	defaultMaxIdleConns        = 100
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultRequestTimeout      = 30 * time.Second
+)
+
+func durationOrDefault(seconds int, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func intOrDefault(n, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
+// buildHTTPClient builds the client used for every Surfline and NWS
+// request. It honors ALL_PROXY via golang.org/x/net/proxy and
+// HTTPS_PROXY/HTTP_PROXY/NO_PROXY via http.ProxyFromEnvironment, applies the
+// dial/idle/handshake timeouts configured under the http: block, and caps
+// the whole request (including a hung TLS handshake or a server that never
+// responds) at requestTimeout so the transport itself aborts it instead of
+// leaking the goroutine and socket past the retry window.
+func buildHTTPClient(cfg Config, requestTimeout time.Duration) *http.Client {
+	forward := &net.Dialer{
+		Timeout:   durationOrDefault(cfg.Http.DialTimeoutSeconds, defaultDialTimeout),
+		KeepAlive: durationOrDefault(cfg.Http.DialKeepAliveSeconds, defaultDialKeepAlive),
+	}
+
+	dialer := proxy.FromEnvironmentUsing(forward)
+
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        intOrDefault(cfg.Http.MaxIdleConns, defaultMaxIdleConns),
+		IdleConnTimeout:     durationOrDefault(cfg.Http.IdleConnTimeoutSeconds, defaultIdleConnTimeout),
+		TLSHandshakeTimeout: durationOrDefault(cfg.Http.TLSHandshakeTimeoutSeconds, defaultTLSHandshakeTimeout),
+	}
+
+	if ctxDialer, ok := dialer.(proxy.ContextDialer); ok {
+		transport.DialContext = ctxDialer.DialContext
+	} else {
+		transport.Dial = dialer.Dial
+	}
+
+	return &http.Client{Transport: transport, Timeout: requestTimeout}
+}
+
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
 
+	warmRun := flag.Bool("warm", false, "run every spot once immediately at startup, before waiting for the first cron tick")
+	flag.Parse()
+
 	var cfg Config
 
 	dir, _ := filepath.Abs(filepath.Dir(os.Args[0]))
@@ -46,311 +164,266 @@ func main() {
 		log.Fatalf("Failed to unmarshal config: %v", err)
 	}
 
-	token, err := os.ReadFile(filepath.Join(dir, "secrets.txt"))
-
-	if err != nil {
-		log.Fatalf("Failed to read secrets file: %v", err)
-	}
-
-	influxDBUrl := cfg.InfluxDB.Url
-	influxDBToken := string(token)
-	influxDBOrg := cfg.InfluxDB.Org
-	influxDBBucket := cfg.InfluxDB.Bucket
-
 	// Setup Surfline API
-	client := &http.Client{}
-	api := &surflineapi.SurflineAPI{
+	requestTimeout := durationOrDefault(cfg.Http.RequestTimeoutSeconds, defaultRequestTimeout)
+	client := buildHTTPClient(cfg, requestTimeout)
+	surflineAPI := &surflineapi.SurflineAPI{
 		HTTPClient: client,
 	}
+	nwsClient := nws.NewClient(client)
+	spotResolver := spotresolver.New(client, filepath.Join(dir, "spot_cache.json"))
+
+	// Setup InfluxDB client. This is opt-in: a user who only configures
+	// mqtt: should be able to run without an influxdb: block or a
+	// secrets.txt token file at all.
+	var writeAPI api.WriteAPIBlocking
+	var sinks []sink.ForecastSink
+	if cfg.InfluxDB.Url != "" {
+		token, err := os.ReadFile(filepath.Join(dir, "secrets.txt"))
+		if err != nil {
+			log.Fatalf("Failed to read secrets file: %v", err)
+		}
 
-	// Setup InfluxDB client
-	influxClient := influxdb2.NewClient(influxDBUrl, influxDBToken)
-	defer influxClient.Close()
-
-	writeAPI := influxClient.WriteAPIBlocking(influxDBOrg, influxDBBucket)
-
-	days, timeInterval := 5, 1
-
-	// Declare a WaitGroup to wait for all goroutines to finish
-	var wg sync.WaitGroup
+		influxClient := influxdb2.NewClient(cfg.InfluxDB.Url, string(token))
+		defer influxClient.Close()
 
-	errCh := make(chan error, len(cfg.Spots))
-	doneCh := make(chan bool, len(cfg.Spots))
-
-	// Iterate over the spots map
-	for _, spotID := range cfg.Spots {
-		go func(spot string) {
-			retries := 0
-			for retries < maxRetries {
-				if err := fetchAndInsert(spot, days, timeInterval, writeAPI, api); err != nil {
-					retries++
-					log.Printf("Error on attempt %d for spot %s: %v", retries, spot, err)
-					if retries < maxRetries {
-						time.Sleep(retryDelay)
-						continue
-					}
-					errCh <- err
-				} else {
-					doneCh <- true
-					break
-				}
-			}
-		}(spotID)
+		writeAPI = influxClient.WriteAPIBlocking(cfg.InfluxDB.Org, cfg.InfluxDB.Bucket)
+		sinks = append(sinks, influxsink.New(writeAPI))
 	}
 
-	// Wait for all goroutines to finish
-	wg.Wait()
-
-	for i := 0; i < len(cfg.Spots); i++ {
-		select {
-		case err := <-errCh:
-			log.Println("Error:", err)
-		case <-doneCh:
-			continue
+	if cfg.Mqtt.Broker != "" {
+		mqttSink, err := mqttsink.New(mqttsink.Config{
+			BrokerURL:   cfg.Mqtt.Broker,
+			ClientID:    cfg.Mqtt.ClientId,
+			Username:    cfg.Mqtt.Username,
+			Password:    cfg.Mqtt.Password,
+			TopicPrefix: cfg.Mqtt.TopicPrefix,
+			QoS:         cfg.Mqtt.Qos,
+			KeepAlive:   time.Duration(cfg.Mqtt.KeepAlive) * time.Second,
+		})
+		if err != nil {
+			log.Fatalf("Failed to connect to MQTT broker: %v", err)
 		}
+		defer mqttSink.Close()
+		sinks = append(sinks, mqttSink)
 	}
-}
 
-func fetchAndInsert(spotId string, days int, timeInterval int, writeAPI api.WriteAPIBlocking, api *surflineapi.SurflineAPI) error {
-	if windForecast, err := api.GetWindForecast(spotId, days, timeInterval, true, true); err != nil {
-		return fmt.Errorf("error fetching wind forecast for %s: %w", spotId, err)
-	} else {
-		insertWindForecastToInflux(spotId, windForecast, writeAPI)
-	}
+	statsRegistry := stats.NewRegistry()
 
-	if waveForecast, err := api.GetWaveForecast(spotId, days, timeInterval); err == nil {
-		return fmt.Errorf("error fetching wave forecast for %s: %w", spotId, err)
-	} else {
-		insertWaveForecastToInflux(spotId, waveForecast, writeAPI)
+	if cfg.Http.Listen != "" {
+		cache := httpapi.NewCache()
+		sinks = append(sinks, cache)
+
+		server := httpapi.NewServer(cfg.Http.Listen, cache, statsRegistry)
+		go func() {
+			log.Printf("Serving cached forecasts on %s", cfg.Http.Listen)
+			if err := server.ListenAndServe(); err != nil {
+				log.Fatalf("HTTP API server failed: %v", err)
+			}
+		}()
 	}
 
-	if tideForecast, err := api.GetTideForecast(spotId, days); err != nil {
-		return fmt.Errorf("error fetching tide forecast for %s: %w", spotId, err)
-	} else {
-		insertTideForecastToInflux(spotId, tideForecast, writeAPI)
+	days, timeInterval := 5, 1
+	concurrency := intOrDefault(cfg.Concurrency, runtime.NumCPU())
+
+	// Resolve every configured spot's metadata once up front so the first
+	// scheduled fetch already has a real name/region instead of the spot ID.
+	for _, spot := range cfg.Spots {
+		spotResolver.Resolve(spot.ID)
 	}
 
-	if ratingForecast, err := api.GetSpotForecastRating(spotId, days, timeInterval); err != nil {
-		return fmt.Errorf("error fetching spot forecast rating for %s: %w", spotId, err)
-	} else {
-		insertSpotForecastRatingToInflux(spotId, ratingForecast, writeAPI)
+	// Tracks in-flight jobs so shutdown can wait for them to drain before
+	// the InfluxDB client (and its write buffer) is closed.
+	var wg sync.WaitGroup
+
+	// jobs feeds a fixed-size pool of workers with one job per spot per
+	// forecast type, so a broken endpoint for one forecast type never
+	// blocks the others for the same spot.
+	jobs := make(chan forecastJob, concurrency*len(forecastTypes))
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			for job := range jobs {
+				runJob(job, days, timeInterval, sinks, writeAPI, surflineAPI, nwsClient, spotResolver, statsRegistry)
+				wg.Done()
+			}
+		}()
 	}
 
-	return nil
-}
+	runSpot := func(spot SpotConfig) {
+		// Add to the WaitGroup synchronously, before the goroutine is
+		// spawned, so a shutdown racing a just-fired tick always sees the
+		// work as in-flight and waits for it instead of closing jobs out
+		// from under a goroutine still sleeping off its jitter.
+		wg.Add(len(forecastTypes))
+		go func() {
+			if jitter := time.Duration(rand.Int63n(int64(maxStartupJitter))); jitter > 0 {
+				time.Sleep(jitter)
+			}
 
-// Helper function to get the friendly name
-// TODO programatically fetch spot name
-func getFriendlyNameForSpot(spotId string) string {
-	switch spotId {
-	case "5842041f4e65fad6a7708841":
-		return "Pacific Beach"
-	case "5842041f4e65fad6a770883c":
-		return "Windansea Beach"
-	case "5842041f4e65fad6a77088cc":
-		return "La Jolla Shores"
-	case "5842041f4e65fad6a770883f":
-		return "Ocean Beach"
-	default:
-		return "Unknown"
+			for _, forecastType := range forecastTypes {
+				jobs <- forecastJob{spotID: spot.ID, forecastType: forecastType}
+			}
+		}()
 	}
-}
 
-func insertWaveForecastToInflux(spotId string, waveForecast *surflineapi.WaveForecastResponse, writeAPI api.WriteAPIBlocking) {
-	for _, waveData := range waveForecast.Data.Wave {
-		forecastTime := time.Unix(waveData.Timestamp, 0)
-		currentTime := time.Now().UTC()
-		forecastAgeHours := int(currentTime.Sub(forecastTime).Hours())
-
-		fields := map[string]interface{}{
-			"probability":   waveData.Probability,
-			"minSurf":       waveData.Surf.Min,
-			"maxSurf":       waveData.Surf.Max,
-			"optimalScore":  waveData.Surf.OptimalScore,
-			"humanRelation": waveData.Surf.HumanRelation,
-			"rawMinSurf":    waveData.Surf.Raw.Min,
-			"rawMaxSurf":    waveData.Surf.Raw.Max,
-			"power":         waveData.Power,
-			"utcOffset":     waveData.UtcOffset,
+	c := cron.New()
+	for _, spot := range cfg.Spots {
+		spot := spot
+		if _, err := c.AddFunc(spot.Schedule, func() { runSpot(spot) }); err != nil {
+			log.Fatalf("Failed to schedule spot %s with cron expression %q: %v", spot.ID, spot.Schedule, err)
 		}
+	}
 
-		tagsWithAge := map[string]string{
-			"spotId":   spotId,
-			"spotName": getFriendlyNameForSpot(spotId),
-			"age_h":    fmt.Sprintf("%d", forecastAgeHours),
+	if *warmRun {
+		log.Println("Warm run: fetching every spot immediately before the first cron tick")
+		for _, spot := range cfg.Spots {
+			runSpot(spot)
 		}
+	}
 
-		tagsWithoutAge := map[string]string{
-			"spotId":   spotId,
-			"spotName": getFriendlyNameForSpot(spotId),
-		}
+	c.Start()
 
-		// Writing wave forecast data
-		pWithAge := influxdb2.NewPoint("waveForecast", tagsWithAge, fields, time.Unix(waveData.Timestamp, 0))
-		err := writeAPI.WritePoint(context.Background(), pWithAge)
-		if err != nil {
-			fmt.Println("Error writing to InfluxDB with age_h tag:", err)
-		}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	sig := <-sigCh
+	log.Printf("Received %v, shutting down", sig)
 
-		pWithoutAge := influxdb2.NewPoint("waveForecast", tagsWithoutAge, fields, time.Unix(waveData.Timestamp, 0))
-		err = writeAPI.WritePoint(context.Background(), pWithoutAge)
-		if err != nil {
-			fmt.Println("Error writing to InfluxDB without age_h tag:", err)
-		}
+	// Stop the scheduler from firing new jobs and wait for the jobs
+	// already running to finish so their writes aren't lost.
+	stopCtx := c.Stop()
+	<-stopCtx.Done()
+	wg.Wait()
+	close(jobs)
 
-		// Writing swells data
-		for _, swell := range waveData.Swells {
-			swellFields := map[string]interface{}{
-				"height":       swell.Height,
-				"period":       swell.Period,
-				"impact":       swell.Impact,
-				"power":        swell.Power,
-				"direction":    swell.Direction,
-				"directionMin": swell.DirectionMin,
-				"optimalScore": swell.OptimalScore,
-			}
+	log.Println("All spots drained, flushing InfluxDB writes")
+	log.Printf("Fetch summary:\n%s", statsRegistry.String())
+}
 
-			pSwellWithAge := influxdb2.NewPoint("swellForecast", tagsWithAge, swellFields, time.Unix(waveData.Timestamp, 0))
-			err := writeAPI.WritePoint(context.Background(), pSwellWithAge)
-			if err != nil {
-				fmt.Println("Error writing swell to InfluxDB with age_h tag:", err)
-			}
+// runJob fetches and writes a single forecast type for a single spot,
+// making up to maxRetries attempts before giving up, and records the
+// outcome of every attempt in registry.
+func runJob(job forecastJob, days int, timeInterval int, sinks []sink.ForecastSink, writeAPI api.WriteAPIBlocking, surflineAPI *surflineapi.SurflineAPI, nwsClient *nws.Client, spotResolver *spotresolver.Resolver, registry *stats.Registry) {
+	retries := 0
+	for {
+		start := time.Now()
+		err := fetchOne(job, days, timeInterval, sinks, writeAPI, surflineAPI, nwsClient, spotResolver)
+		registry.Record(job.spotID, job.forecastType, err, time.Since(start))
 
-			pSwellWithoutAge := influxdb2.NewPoint("swellForecast", tagsWithoutAge, swellFields, time.Unix(waveData.Timestamp, 0))
-			err = writeAPI.WritePoint(context.Background(), pSwellWithoutAge)
-			if err != nil {
-				fmt.Println("Error writing swell to InfluxDB without age_h tag:", err)
+		if err != nil {
+			retries++
+			log.Printf("Error on attempt %d for spot %s %s forecast: %v", retries, job.spotID, job.forecastType, err)
+			if retries < maxRetries {
+				time.Sleep(retryDelay)
+				continue
 			}
+			log.Printf("Giving up on spot %s %s forecast after %d attempts", job.spotID, job.forecastType, retries)
 		}
+		return
 	}
 }
 
-// insertWindForecastToInflux writes wind forecast data to InfluxDB
-func insertWindForecastToInflux(spotId string, windForecast *surflineapi.WindForecastResponse, writeAPI api.WriteAPIBlocking) {
-	for _, windDetail := range windForecast.Data.Wind {
-
-		forecastTime := time.Unix(windDetail.Timestamp, 0)
-		currentTime := time.Now().UTC()
-		forecastAgeHours := int(currentTime.Sub(forecastTime).Hours())
-
-		tagsWithAge := map[string]string{
-			"location": fmt.Sprintf("%f,%f", windForecast.Associated.Location.Lat, windForecast.Associated.Location.Lon),
-			"spotId":   spotId,
-			"spotName": getFriendlyNameForSpot(spotId),
-			"age_h":    fmt.Sprintf("%d", forecastAgeHours),
-		}
+// fetchOne fetches and writes the single forecast type named by job,
+// leaving the other forecast types for the same spot unaffected by its
+// success or failure.
+func fetchOne(job forecastJob, days int, timeInterval int, sinks []sink.ForecastSink, writeAPI api.WriteAPIBlocking, surflineAPI *surflineapi.SurflineAPI, nwsClient *nws.Client, spotResolver *spotresolver.Resolver) error {
+	spotId := job.spotID
+	meta := spotResolver.Resolve(spotId)
 
-		tagsWithoutAge := map[string]string{
-			"location": fmt.Sprintf("%f,%f", windForecast.Associated.Location.Lat, windForecast.Associated.Location.Lon),
-			"spotId":   spotId,
-			"spotName": getFriendlyNameForSpot(spotId),
-		}
-
-		fields := map[string]interface{}{
-			"speed":         windDetail.Speed,
-			"direction":     windDetail.Direction,
-			"directionType": windDetail.DirectionType,
-			"gust":          windDetail.Gust,
-			"optimalScore":  windDetail.OptimalScore,
-			"utcOffset":     windDetail.UtcOffset,
-		}
-
-		// Writing point with age_h tag
-		pWithAge := influxdb2.NewPoint("windForecast", tagsWithAge, fields, time.Unix(windDetail.Timestamp, 0))
-		err := writeAPI.WritePoint(context.Background(), pWithAge)
+	switch job.forecastType {
+	case "wind":
+		windForecast, err := surflineAPI.GetWindForecast(spotId, days, timeInterval, true, true)
 		if err != nil {
-			fmt.Println("Error writing to InfluxDB with age_h tag:", err)
+			return fmt.Errorf("error fetching wind forecast for %s: %w", spotId, err)
+		}
+		for _, s := range sinks {
+			if err := s.WriteWind(meta, windForecast); err != nil {
+				fmt.Println("Error writing wind forecast:", err)
+			}
 		}
+		insertNWSForecastToInflux(meta, windForecast.Associated.Location, nwsClient, writeAPI)
 
-		// Writing point without age_h tag
-		pWithoutAge := influxdb2.NewPoint("windForecast", tagsWithoutAge, fields, time.Unix(windDetail.Timestamp, 0))
-		err = writeAPI.WritePoint(context.Background(), pWithoutAge)
+	case "wave":
+		waveForecast, err := surflineAPI.GetWaveForecast(spotId, days, timeInterval)
 		if err != nil {
-			fmt.Println("Error writing to InfluxDB without age_h tag:", err)
+			return fmt.Errorf("error fetching wave forecast for %s: %w", spotId, err)
 		}
-	}
-}
-func insertTideForecastToInflux(spotId string, tideForecast *surflineapi.TideForecastResponse, writeAPI api.WriteAPIBlocking) {
-	for _, tideInfo := range tideForecast.Data.Tides {
-
-		forecastTime := time.Unix(tideInfo.Timestamp, 0)
-		currentTime := time.Now().UTC()
-		forecastAgeHours := int(currentTime.Sub(forecastTime).Hours())
-
-		tagsWithAge := map[string]string{
-			"location": fmt.Sprintf("%f,%f", tideForecast.Associated.TideLocation.Lat, tideForecast.Associated.TideLocation.Lon),
-			"name":     tideForecast.Associated.TideLocation.Name,
-			"spotId":   spotId,
-			"spotName": getFriendlyNameForSpot(spotId),
-			"age_h":    fmt.Sprintf("%d", forecastAgeHours),
+		for _, s := range sinks {
+			if err := s.WriteWave(meta, waveForecast); err != nil {
+				fmt.Println("Error writing wave forecast:", err)
+			}
 		}
 
-		tagsWithoutAge := map[string]string{
-			"location": fmt.Sprintf("%f,%f", tideForecast.Associated.TideLocation.Lat, tideForecast.Associated.TideLocation.Lon),
-			"name":     tideForecast.Associated.TideLocation.Name,
-			"spotId":   spotId,
-			"spotName": getFriendlyNameForSpot(spotId),
+	case "tide":
+		tideForecast, err := surflineAPI.GetTideForecast(spotId, days)
+		if err != nil {
+			return fmt.Errorf("error fetching tide forecast for %s: %w", spotId, err)
 		}
-
-		fields := map[string]interface{}{
-			"type":      tideInfo.Type,
-			"height":    tideInfo.Height,
-			"utcOffset": tideInfo.UtcOffset,
+		for _, s := range sinks {
+			if err := s.WriteTide(meta, tideForecast); err != nil {
+				fmt.Println("Error writing tide forecast:", err)
+			}
 		}
 
-		// Writing point with age_h tag
-		pWithAge := influxdb2.NewPoint("tideForecast", tagsWithAge, fields, time.Unix(tideInfo.Timestamp, 0))
-		err := writeAPI.WritePoint(context.Background(), pWithAge)
+	case "rating":
+		ratingForecast, err := surflineAPI.GetSpotForecastRating(spotId, days, timeInterval)
 		if err != nil {
-			fmt.Println("Error writing to InfluxDB with age_h tag:", err)
+			return fmt.Errorf("error fetching spot forecast rating for %s: %w", spotId, err)
 		}
-
-		// Writing point without age_h tag
-		pWithoutAge := influxdb2.NewPoint("tideForecast", tagsWithoutAge, fields, time.Unix(tideInfo.Timestamp, 0))
-		err = writeAPI.WritePoint(context.Background(), pWithoutAge)
-		if err != nil {
-			fmt.Println("Error writing to InfluxDB without age_h tag:", err)
+		for _, s := range sinks {
+			if err := s.WriteRating(meta, ratingForecast); err != nil {
+				fmt.Println("Error writing rating forecast:", err)
+			}
 		}
-
 	}
-}
 
-func insertSpotForecastRatingToInflux(spotId string, ratingForecast *surflineapi.SpotForecastRatingResponse, writeAPI api.WriteAPIBlocking) {
-	for _, rating := range ratingForecast.Data.Rating {
-		forecastTime := time.Unix(rating.Timestamp, 0).UTC()
-		currentTime := time.Now().UTC()
-		forecastAgeHours := int(currentTime.Sub(forecastTime).Hours())
+	return nil
+}
 
-		fields := map[string]interface{}{
-			"ratingValue": rating.Rating.Value,
-			"utcOffset":   rating.UtcOffset,
-		}
-		tagsWithAge := map[string]string{
-			"spotId":    spotId,
-			"ratingKey": rating.Rating.Key,
-			"spotName":  getFriendlyNameForSpot(spotId),
-			"age_h":     fmt.Sprintf("%d", forecastAgeHours),
-		}
+// insertNWSForecastToInflux cross-checks a spot's Surfline forecast against
+// the National Weather Service's free gridpoint forecast for the same
+// location, so dashboards can overlay the two sources.
+func insertNWSForecastToInflux(meta sink.SpotMeta, location surflineapi.LatLng, nwsClient *nws.Client, writeAPI api.WriteAPIBlocking) {
+	if writeAPI == nil {
+		return
+	}
 
-		tagsWithoutAge := map[string]string{
-			"spotId":    spotId,
-			"ratingKey": rating.Rating.Key,
-			"spotName":  getFriendlyNameForSpot(spotId),
-			"age_h":     fmt.Sprintf("%d", forecastAgeHours),
-		}
+	grid, err := nwsClient.GetGridpoint(location.Lat, location.Lon)
+	if err != nil {
+		fmt.Println("Error resolving NWS gridpoint:", err)
+		return
+	}
 
-		// Writing point with age_h tag
-		pWithAge := influxdb2.NewPoint("spotForecast", tagsWithAge, fields, time.Unix(rating.Timestamp, 0))
-		err := writeAPI.WritePoint(context.Background(), pWithAge)
+	for _, interval := range []struct {
+		name   string
+		hourly bool
+	}{{"daily", false}, {"hourly", true}} {
+		periods, err := nwsClient.GetForecastPeriods(grid, interval.hourly)
 		if err != nil {
-			fmt.Println("Error writing to InfluxDB with age_h tag:", err)
+			fmt.Println("Error fetching NWS forecast:", err)
+			continue
 		}
 
-		// Writing point without age_h tag
-		pWithoutAge := influxdb2.NewPoint("spotForecast", tagsWithoutAge, fields, time.Unix(rating.Timestamp, 0))
-		err = writeAPI.WritePoint(context.Background(), pWithoutAge)
-		if err != nil {
-			fmt.Println("Error writing to InfluxDB without age_h tag:", err)
+		for _, period := range periods {
+			tags := map[string]string{
+				"spotId":   meta.ID,
+				"spotName": meta.Name,
+				"gridId":   grid.GridId,
+				"gridX":    fmt.Sprintf("%d", grid.GridX),
+				"gridY":    fmt.Sprintf("%d", grid.GridY),
+				"interval": interval.name,
+			}
+
+			fields := map[string]interface{}{
+				"temperature":   period.Temperature,
+				"windSpeed":     period.WindSpeed,
+				"windDirection": period.WindDirection,
+				"shortForecast": period.ShortForecast,
+			}
+
+			p := influxdb2.NewPoint("nwsForecast", tags, fields, period.StartTime)
+			if err := writeAPI.WritePoint(context.Background(), p); err != nil {
+				fmt.Println("Error writing NWS forecast to InfluxDB:", err)
+			}
 		}
 	}
 }
@@ -0,0 +1,154 @@
+// Package httpapi is an opt-in HTTP server that exposes the most recently
+// fetched forecast for each spot, as an on-demand read path for users who
+// don't want to query InfluxDB directly, and as a liveness endpoint for
+// k8s deployments.
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	surflineapi "github.com/mdecourcy/go-surfline-api/pkg/surflineapi"
+
+	"github.com/macdecourcy/surfline2influxdb/pkg/sink"
+	"github.com/macdecourcy/surfline2influxdb/pkg/stats"
+)
+
+// Cache holds the most recent successfully-fetched forecast for each spot
+// and forecast type. It implements sink.ForecastSink, so it can be added
+// to fetchOne's sink list alongside InfluxDB/MQTT.
+type Cache struct {
+	forecasts sync.Map // key: spotId+"/"+forecastType -> cached forecast
+	spots     sync.Map // key: spotId -> struct{}, tracks spots seen so far
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+func cacheKey(spotId, forecastType string) string {
+	return spotId + "/" + forecastType
+}
+
+func (c *Cache) store(spotId, forecastType string, forecast interface{}) {
+	c.spots.Store(spotId, struct{}{})
+	c.forecasts.Store(cacheKey(spotId, forecastType), forecast)
+}
+
+// Get returns the most recently cached forecast of forecastType for spotId.
+func (c *Cache) Get(spotId, forecastType string) (interface{}, bool) {
+	return c.forecasts.Load(cacheKey(spotId, forecastType))
+}
+
+// Spots returns the IDs of every spot the cache has seen a forecast for.
+func (c *Cache) Spots() []string {
+	var spots []string
+	c.spots.Range(func(key, _ interface{}) bool {
+		spots = append(spots, key.(string))
+		return true
+	})
+	return spots
+}
+
+func (c *Cache) WriteWave(meta sink.SpotMeta, forecast *surflineapi.WaveForecastResponse) error {
+	c.store(meta.ID, "wave", forecast)
+	return nil
+}
+
+func (c *Cache) WriteWind(meta sink.SpotMeta, forecast *surflineapi.WindForecastResponse) error {
+	c.store(meta.ID, "wind", forecast)
+	return nil
+}
+
+func (c *Cache) WriteTide(meta sink.SpotMeta, forecast *surflineapi.TideForecastResponse) error {
+	c.store(meta.ID, "tide", forecast)
+	return nil
+}
+
+func (c *Cache) WriteRating(meta sink.SpotMeta, forecast *surflineapi.SpotForecastRatingResponse) error {
+	c.store(meta.ID, "rating", forecast)
+	return nil
+}
+
+// Server serves the cached forecasts over HTTP.
+type Server struct {
+	addr  string
+	cache *Cache
+	stats *stats.Registry
+}
+
+// NewServer returns a Server that will listen on addr and serve forecasts
+// out of cache. /healthz and /metrics report fetch outcomes recorded in
+// registry.
+func NewServer(addr string, cache *Cache, registry *stats.Registry) *Server {
+	return &Server{addr: addr, cache: cache, stats: registry}
+}
+
+// ListenAndServe starts the HTTP server. It blocks until the server stops
+// or fails to start.
+func (s *Server) ListenAndServe() error {
+	return http.ListenAndServe(s.addr, s.mux())
+}
+
+func (s *Server) mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.Handle("/metrics", s.stats.Handler())
+	mux.HandleFunc("/spots", s.handleSpots)
+	mux.HandleFunc("/spots/", s.handleSpotForecast)
+	return mux
+}
+
+// handleHealthz reports liveness plus a structured summary of every
+// spot/forecast type fetched so far, so operators can see which endpoints
+// are failing without cross-referencing logs.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, struct {
+		Status  string           `json:"status"`
+		Fetches []stats.Snapshot `json:"fetches"`
+	}{
+		Status:  "ok",
+		Fetches: s.stats.Snapshot(),
+	})
+}
+
+func (s *Server) handleSpots(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.cache.Spots())
+}
+
+// handleSpotForecast serves GET /spots/{id}/{forecastType}.
+func (s *Server) handleSpotForecast(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/spots/"), "/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	spotId, forecastType := parts[0], parts[1]
+
+	switch forecastType {
+	case "wave", "wind", "tide", "rating":
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	forecast, ok := s.cache.Get(spotId, forecastType)
+	if !ok {
+		http.Error(w, fmt.Sprintf("no %s forecast cached yet for spot %s", forecastType, spotId), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, forecast)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,99 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	surflineapi "github.com/mdecourcy/go-surfline-api/pkg/surflineapi"
+
+	"github.com/macdecourcy/surfline2influxdb/pkg/sink"
+	"github.com/macdecourcy/surfline2influxdb/pkg/stats"
+)
+
+func newTestServer() (*Server, *Cache) {
+	cache := NewCache()
+	return NewServer(":0", cache, stats.NewRegistry()), cache
+}
+
+func do(t *testing.T, s *Server, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.mux().ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func TestHandleSpotForecast_NotFoundBeforeCached(t *testing.T) {
+	s, _ := newTestServer()
+
+	rec := do(t, s, http.MethodGet, "/spots/pipeline/wave")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an uncached forecast, got %d", rec.Code)
+	}
+}
+
+func TestHandleSpotForecast_UnknownType(t *testing.T) {
+	s, cache := newTestServer()
+	cache.WriteWave(sink.SpotMeta{ID: "pipeline"}, &surflineapi.WaveForecastResponse{})
+
+	rec := do(t, s, http.MethodGet, "/spots/pipeline/swell")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unrecognized forecast type, got %d", rec.Code)
+	}
+}
+
+func TestHandleSpotForecast_ServesCachedForecast(t *testing.T) {
+	s, cache := newTestServer()
+	cache.WriteWave(sink.SpotMeta{ID: "pipeline"}, &surflineapi.WaveForecastResponse{})
+
+	rec := do(t, s, http.MethodGet, "/spots/pipeline/wave")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a cached forecast, got %d", rec.Code)
+	}
+}
+
+func TestHandleSpots_ListsSpotsSeenByTheCache(t *testing.T) {
+	s, cache := newTestServer()
+	cache.WriteWave(sink.SpotMeta{ID: "pipeline"}, &surflineapi.WaveForecastResponse{})
+	cache.WriteTide(sink.SpotMeta{ID: "trestles"}, &surflineapi.TideForecastResponse{})
+
+	rec := do(t, s, http.MethodGet, "/spots")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var spots []string
+	if err := json.Unmarshal(rec.Body.Bytes(), &spots); err != nil {
+		t.Fatalf("failed to decode /spots response: %v", err)
+	}
+	if len(spots) != 2 {
+		t.Fatalf("expected 2 spots, got %v", spots)
+	}
+}
+
+func TestHandleHealthz_ReportsRecordedFetches(t *testing.T) {
+	cache := NewCache()
+	registry := stats.NewRegistry()
+	registry.Record("pipeline", "wave", nil, 0)
+
+	s := NewServer(":0", cache, registry)
+	rec := do(t, s, http.MethodGet, "/healthz")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Status  string           `json:"status"`
+		Fetches []stats.Snapshot `json:"fetches"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode /healthz response: %v", err)
+	}
+	if body.Status != "ok" {
+		t.Fatalf("expected status ok, got %q", body.Status)
+	}
+	if len(body.Fetches) != 1 || body.Fetches[0].SpotID != "pipeline" {
+		t.Fatalf("expected the recorded pipeline/wave fetch, got %v", body.Fetches)
+	}
+}
@@ -0,0 +1,105 @@
+// Package nws is a small client for the National Weather Service's public
+// api.weather.gov API, used to cross-check Surfline's proprietary forecasts
+// against a free, authoritative source.
+package nws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const baseURL = "https://api.weather.gov"
+
+// Client fetches NWS gridpoint forecasts.
+type Client struct {
+	HTTPClient *http.Client
+}
+
+// NewClient returns a Client that uses httpClient for requests.
+func NewClient(httpClient *http.Client) *Client {
+	return &Client{HTTPClient: httpClient}
+}
+
+// Gridpoint identifies the NWS forecast office grid cell covering a point.
+type Gridpoint struct {
+	GridId string
+	GridX  int
+	GridY  int
+}
+
+type pointsResponse struct {
+	Properties struct {
+		GridId string `json:"gridId"`
+		GridX  int    `json:"gridX"`
+		GridY  int    `json:"gridY"`
+	} `json:"properties"`
+}
+
+// Period is a single NWS forecast period (typically 1h or 12h wide).
+type Period struct {
+	StartTime     time.Time `json:"startTime"`
+	Temperature   float64   `json:"temperature"`
+	WindSpeed     string    `json:"windSpeed"`
+	WindDirection string    `json:"windDirection"`
+	ShortForecast string    `json:"shortForecast"`
+}
+
+type forecastResponse struct {
+	Properties struct {
+		Periods []Period `json:"periods"`
+	} `json:"properties"`
+}
+
+func (c *Client) fetch(url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	// api.weather.gov requires a descriptive User-Agent on every request.
+	req.Header.Set("User-Agent", "surfline2influxdb (https://github.com/mdecourcy/surfline2influxdb)")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("nws: unexpected status %d for %s", resp.StatusCode, url)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// GetGridpoint resolves a lat/lon to the NWS grid cell that covers it.
+func (c *Client) GetGridpoint(lat, lon float64) (*Gridpoint, error) {
+	url := fmt.Sprintf("%s/points/%f,%f", baseURL, lat, lon)
+	var points pointsResponse
+	if err := c.fetch(url, &points); err != nil {
+		return nil, fmt.Errorf("error resolving gridpoint for %f,%f: %w", lat, lon, err)
+	}
+	return &Gridpoint{
+		GridId: points.Properties.GridId,
+		GridX:  points.Properties.GridX,
+		GridY:  points.Properties.GridY,
+	}, nil
+}
+
+// GetForecastPeriods fetches the forecast periods for a grid cell. Set
+// hourly to true for the hourly forecast, false for the standard (day/night)
+// forecast.
+func (c *Client) GetForecastPeriods(grid *Gridpoint, hourly bool) ([]Period, error) {
+	endpoint := "forecast"
+	if hourly {
+		endpoint = "forecast/hourly"
+	}
+	url := fmt.Sprintf("%s/gridpoints/%s/%d,%d/%s", baseURL, grid.GridId, grid.GridX, grid.GridY, endpoint)
+
+	var forecast forecastResponse
+	if err := c.fetch(url, &forecast); err != nil {
+		return nil, fmt.Errorf("error fetching %s forecast for gridpoint %s/%d,%d: %w", endpoint, grid.GridId, grid.GridX, grid.GridY, err)
+	}
+	return forecast.Properties.Periods, nil
+}
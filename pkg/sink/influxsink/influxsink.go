@@ -0,0 +1,209 @@
+// Package influxsink is the sink.ForecastSink implementation backed by
+// InfluxDB. It is the sink this project shipped with before the sink
+// interface existed; fetchAndInsert used to write to InfluxDB directly.
+package influxsink
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	surflineapi "github.com/mdecourcy/go-surfline-api/pkg/surflineapi"
+
+	"github.com/macdecourcy/surfline2influxdb/pkg/sink"
+)
+
+// InfluxSink writes forecasts to InfluxDB via a blocking write API.
+type InfluxSink struct {
+	writeAPI api.WriteAPIBlocking
+}
+
+// New returns an InfluxSink that writes through writeAPI.
+func New(writeAPI api.WriteAPIBlocking) *InfluxSink {
+	return &InfluxSink{writeAPI: writeAPI}
+}
+
+func (s *InfluxSink) writePoint(measurement string, tags map[string]string, fields map[string]interface{}, ts time.Time) error {
+	p := influxdb2.NewPoint(measurement, tags, fields, ts)
+	return s.writeAPI.WritePoint(context.Background(), p)
+}
+
+// metaTags is the set of tags every measurement carries for a spot.
+// Region/subregion/timezone are omitted when the resolver couldn't
+// determine them, so dashboards can still group by spotId/spotName.
+func metaTags(meta sink.SpotMeta) map[string]string {
+	tags := map[string]string{
+		"spotId":   meta.ID,
+		"spotName": meta.Name,
+	}
+	if meta.Region != "" {
+		tags["region"] = meta.Region
+	}
+	if meta.Subregion != "" {
+		tags["subregion"] = meta.Subregion
+	}
+	if meta.Timezone != "" {
+		tags["timezone"] = meta.Timezone
+	}
+	return tags
+}
+
+func withTags(base map[string]string, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(extra))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+func (s *InfluxSink) WriteWave(meta sink.SpotMeta, waveForecast *surflineapi.WaveForecastResponse) error {
+	var firstErr error
+	baseTags := metaTags(meta)
+
+	for _, waveData := range waveForecast.Data.Wave {
+		forecastTime := time.Unix(waveData.Timestamp, 0)
+		forecastAgeHours := int(time.Now().UTC().Sub(forecastTime).Hours())
+
+		fields := map[string]interface{}{
+			"probability":   waveData.Probability,
+			"minSurf":       waveData.Surf.Min,
+			"maxSurf":       waveData.Surf.Max,
+			"optimalScore":  waveData.Surf.OptimalScore,
+			"humanRelation": waveData.Surf.HumanRelation,
+			"rawMinSurf":    waveData.Surf.Raw.Min,
+			"rawMaxSurf":    waveData.Surf.Raw.Max,
+			"power":         waveData.Power,
+			"utcOffset":     waveData.UtcOffset,
+		}
+
+		tagsWithAge := withTags(baseTags, map[string]string{"age_h": fmt.Sprintf("%d", forecastAgeHours)})
+
+		if err := s.writePoint("waveForecast", tagsWithAge, fields, forecastTime); err != nil {
+			fmt.Println("Error writing to InfluxDB with age_h tag:", err)
+			firstErr = err
+		}
+		if err := s.writePoint("waveForecast", baseTags, fields, forecastTime); err != nil {
+			fmt.Println("Error writing to InfluxDB without age_h tag:", err)
+			firstErr = err
+		}
+
+		for _, swell := range waveData.Swells {
+			swellFields := map[string]interface{}{
+				"height":       swell.Height,
+				"period":       swell.Period,
+				"impact":       swell.Impact,
+				"power":        swell.Power,
+				"direction":    swell.Direction,
+				"directionMin": swell.DirectionMin,
+				"optimalScore": swell.OptimalScore,
+			}
+
+			if err := s.writePoint("swellForecast", tagsWithAge, swellFields, forecastTime); err != nil {
+				fmt.Println("Error writing swell to InfluxDB with age_h tag:", err)
+				firstErr = err
+			}
+			if err := s.writePoint("swellForecast", baseTags, swellFields, forecastTime); err != nil {
+				fmt.Println("Error writing swell to InfluxDB without age_h tag:", err)
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (s *InfluxSink) WriteWind(meta sink.SpotMeta, windForecast *surflineapi.WindForecastResponse) error {
+	var firstErr error
+	location := fmt.Sprintf("%f,%f", windForecast.Associated.Location.Lat, windForecast.Associated.Location.Lon)
+	baseTags := withTags(metaTags(meta), map[string]string{"location": location})
+
+	for _, windDetail := range windForecast.Data.Wind {
+		forecastTime := time.Unix(windDetail.Timestamp, 0)
+		forecastAgeHours := int(time.Now().UTC().Sub(forecastTime).Hours())
+
+		tagsWithAge := withTags(baseTags, map[string]string{"age_h": fmt.Sprintf("%d", forecastAgeHours)})
+
+		fields := map[string]interface{}{
+			"speed":         windDetail.Speed,
+			"direction":     windDetail.Direction,
+			"directionType": windDetail.DirectionType,
+			"gust":          windDetail.Gust,
+			"optimalScore":  windDetail.OptimalScore,
+			"utcOffset":     windDetail.UtcOffset,
+		}
+
+		if err := s.writePoint("windForecast", tagsWithAge, fields, forecastTime); err != nil {
+			fmt.Println("Error writing to InfluxDB with age_h tag:", err)
+			firstErr = err
+		}
+		if err := s.writePoint("windForecast", baseTags, fields, forecastTime); err != nil {
+			fmt.Println("Error writing to InfluxDB without age_h tag:", err)
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s *InfluxSink) WriteTide(meta sink.SpotMeta, tideForecast *surflineapi.TideForecastResponse) error {
+	var firstErr error
+	location := fmt.Sprintf("%f,%f", tideForecast.Associated.TideLocation.Lat, tideForecast.Associated.TideLocation.Lon)
+	baseTags := withTags(metaTags(meta), map[string]string{
+		"location": location,
+		"name":     tideForecast.Associated.TideLocation.Name,
+	})
+
+	for _, tideInfo := range tideForecast.Data.Tides {
+		forecastTime := time.Unix(tideInfo.Timestamp, 0)
+		forecastAgeHours := int(time.Now().UTC().Sub(forecastTime).Hours())
+
+		tagsWithAge := withTags(baseTags, map[string]string{"age_h": fmt.Sprintf("%d", forecastAgeHours)})
+
+		fields := map[string]interface{}{
+			"type":      tideInfo.Type,
+			"height":    tideInfo.Height,
+			"utcOffset": tideInfo.UtcOffset,
+		}
+
+		if err := s.writePoint("tideForecast", tagsWithAge, fields, forecastTime); err != nil {
+			fmt.Println("Error writing to InfluxDB with age_h tag:", err)
+			firstErr = err
+		}
+		if err := s.writePoint("tideForecast", baseTags, fields, forecastTime); err != nil {
+			fmt.Println("Error writing to InfluxDB without age_h tag:", err)
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s *InfluxSink) WriteRating(meta sink.SpotMeta, ratingForecast *surflineapi.SpotForecastRatingResponse) error {
+	var firstErr error
+	baseTags := metaTags(meta)
+
+	for _, rating := range ratingForecast.Data.Rating {
+		forecastTime := time.Unix(rating.Timestamp, 0).UTC()
+		forecastAgeHours := int(time.Now().UTC().Sub(forecastTime).Hours())
+
+		fields := map[string]interface{}{
+			"ratingValue": rating.Rating.Value,
+			"utcOffset":   rating.UtcOffset,
+		}
+
+		tagsWithoutAge := withTags(baseTags, map[string]string{"ratingKey": rating.Rating.Key})
+		tagsWithAge := withTags(tagsWithoutAge, map[string]string{"age_h": fmt.Sprintf("%d", forecastAgeHours)})
+
+		if err := s.writePoint("spotForecast", tagsWithAge, fields, forecastTime); err != nil {
+			fmt.Println("Error writing to InfluxDB with age_h tag:", err)
+			firstErr = err
+		}
+		if err := s.writePoint("spotForecast", tagsWithoutAge, fields, forecastTime); err != nil {
+			fmt.Println("Error writing to InfluxDB without age_h tag:", err)
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,95 @@
+// Package mqttsink is a sink.ForecastSink implementation that publishes
+// forecasts as JSON to an MQTT broker, so they can be consumed by Home
+// Assistant, Node-RED, or anything else that speaks MQTT without requiring
+// an InfluxDB dependency.
+package mqttsink
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	surflineapi "github.com/mdecourcy/go-surfline-api/pkg/surflineapi"
+
+	"github.com/macdecourcy/surfline2influxdb/pkg/sink"
+)
+
+// Config configures the MQTT broker connection and publish behavior.
+type Config struct {
+	BrokerURL   string
+	ClientID    string
+	Username    string
+	Password    string
+	TopicPrefix string
+	QoS         byte
+	KeepAlive   time.Duration
+}
+
+// MQTTSink publishes forecasts to topics under cfg.TopicPrefix, e.g.
+// "surfline/{spotId}/wave".
+type MQTTSink struct {
+	client mqtt.Client
+	cfg    Config
+}
+
+// New connects to the broker described by cfg and returns an MQTTSink. The
+// underlying client reconnects and re-establishes its subscriptions
+// automatically if the broker connection drops.
+func New(cfg Config) (*MQTTSink, error) {
+	opts := mqtt.NewClientOptions().
+		AddBroker(cfg.BrokerURL).
+		SetClientID(cfg.ClientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetKeepAlive(cfg.KeepAlive).
+		SetAutoReconnect(true).
+		SetResumeSubs(true).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			log.Printf("mqtt: connection lost: %v", err)
+		}).
+		SetOnConnectHandler(func(_ mqtt.Client) {
+			log.Println("mqtt: connected")
+		})
+
+	client := mqtt.NewClient(opts)
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		return nil, fmt.Errorf("mqtt: failed to connect to %s: %w", cfg.BrokerURL, token.Error())
+	}
+
+	return &MQTTSink{client: client, cfg: cfg}, nil
+}
+
+// Close disconnects from the broker.
+func (s *MQTTSink) Close() {
+	s.client.Disconnect(250)
+}
+
+func (s *MQTTSink) publish(spotId, forecastType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("mqtt: failed to marshal %s payload for %s: %w", forecastType, spotId, err)
+	}
+
+	topic := fmt.Sprintf("%s/%s/%s", s.cfg.TopicPrefix, spotId, forecastType)
+	token := s.client.Publish(topic, s.cfg.QoS, false, data)
+	token.Wait()
+	return token.Error()
+}
+
+func (s *MQTTSink) WriteWave(meta sink.SpotMeta, forecast *surflineapi.WaveForecastResponse) error {
+	return s.publish(meta.ID, "wave", forecast)
+}
+
+func (s *MQTTSink) WriteWind(meta sink.SpotMeta, forecast *surflineapi.WindForecastResponse) error {
+	return s.publish(meta.ID, "wind", forecast)
+}
+
+func (s *MQTTSink) WriteTide(meta sink.SpotMeta, forecast *surflineapi.TideForecastResponse) error {
+	return s.publish(meta.ID, "tide", forecast)
+}
+
+func (s *MQTTSink) WriteRating(meta sink.SpotMeta, forecast *surflineapi.SpotForecastRatingResponse) error {
+	return s.publish(meta.ID, "rating", forecast)
+}
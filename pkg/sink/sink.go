@@ -0,0 +1,32 @@
+// Package sink defines the pluggable destination for fetched forecasts, so
+// the same fetch loop can fan a spot's forecasts out to InfluxDB, MQTT, or
+// any future backend without fetchOne knowing which one it's talking
+// to.
+package sink
+
+import surflineapi "github.com/mdecourcy/go-surfline-api/pkg/surflineapi"
+
+// SpotMeta is the spot metadata resolved by pkg/spotresolver and threaded
+// through to every sink so it can be attached to each forecast (as
+// InfluxDB tags, MQTT topic segments, etc.) without each sink needing to
+// resolve it itself.
+type SpotMeta struct {
+	ID        string
+	Name      string
+	Lat       float64
+	Lon       float64
+	Timezone  string
+	Region    string
+	Subregion string
+	Country   string
+}
+
+// ForecastSink persists a spot's forecasts. Implementations must be safe
+// for concurrent use, since fetchOne is called from multiple worker
+// goroutines at once.
+type ForecastSink interface {
+	WriteWave(meta SpotMeta, forecast *surflineapi.WaveForecastResponse) error
+	WriteWind(meta SpotMeta, forecast *surflineapi.WindForecastResponse) error
+	WriteTide(meta SpotMeta, forecast *surflineapi.TideForecastResponse) error
+	WriteRating(meta SpotMeta, forecast *surflineapi.SpotForecastRatingResponse) error
+}
@@ -0,0 +1,156 @@
+// Package spotresolver resolves a spot ID to its human-readable metadata
+// (name, location, timezone, region, subregion, country) via Surfline's
+// spot-details endpoint, replacing the hard-coded name lookup this project
+// used to ship with.
+package spotresolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/macdecourcy/surfline2influxdb/pkg/sink"
+)
+
+const baseURL = "https://services.surfline.com/kbyg"
+
+// RefreshInterval is how long a resolved spot's metadata is trusted before
+// Resolve re-fetches it.
+const RefreshInterval = 24 * time.Hour
+
+type cacheEntry struct {
+	Meta      sink.SpotMeta `json:"meta"`
+	FetchedAt time.Time     `json:"fetchedAt"`
+}
+
+// Resolver resolves and caches spot metadata, persisting the cache to disk
+// so restarts don't re-hit Surfline for spots already resolved.
+type Resolver struct {
+	httpClient *http.Client
+	cachePath  string
+	baseURL    string
+
+	mu    sync.RWMutex
+	cache map[string]cacheEntry
+}
+
+// New returns a Resolver that persists its cache to cachePath.
+func New(httpClient *http.Client, cachePath string) *Resolver {
+	r := &Resolver{
+		httpClient: httpClient,
+		cachePath:  cachePath,
+		baseURL:    baseURL,
+		cache:      map[string]cacheEntry{},
+	}
+	r.loadCache()
+	return r
+}
+
+func (r *Resolver) loadCache() {
+	data, err := os.ReadFile(r.cachePath)
+	if err != nil {
+		return
+	}
+
+	var cache map[string]cacheEntry
+	if err := json.Unmarshal(data, &cache); err != nil {
+		fmt.Println("Error reading spot metadata cache, starting empty:", err)
+		return
+	}
+
+	r.mu.Lock()
+	r.cache = cache
+	r.mu.Unlock()
+}
+
+func (r *Resolver) saveCache() {
+	r.mu.RLock()
+	data, err := json.Marshal(r.cache)
+	r.mu.RUnlock()
+	if err != nil {
+		fmt.Println("Error marshaling spot metadata cache:", err)
+		return
+	}
+
+	if err := os.WriteFile(r.cachePath, data, 0o644); err != nil {
+		fmt.Println("Error persisting spot metadata cache:", err)
+	}
+}
+
+// Resolve returns metadata for spotId, fetching it from Surfline if it's
+// missing from the cache or older than RefreshInterval. If the fetch fails,
+// it falls back to the last cached value, or to a SpotMeta with the spot ID
+// standing in for the name.
+func (r *Resolver) Resolve(spotId string) sink.SpotMeta {
+	r.mu.RLock()
+	entry, cached := r.cache[spotId]
+	r.mu.RUnlock()
+
+	if cached && time.Since(entry.FetchedAt) < RefreshInterval {
+		return entry.Meta
+	}
+
+	meta, err := r.fetch(spotId)
+	if err != nil {
+		fmt.Println("Error resolving spot metadata for", spotId, ":", err)
+		if cached {
+			return entry.Meta
+		}
+		return sink.SpotMeta{ID: spotId, Name: spotId}
+	}
+
+	r.mu.Lock()
+	r.cache[spotId] = cacheEntry{Meta: meta, FetchedAt: time.Now()}
+	r.mu.Unlock()
+	r.saveCache()
+
+	return meta
+}
+
+func (r *Resolver) fetch(spotId string) (sink.SpotMeta, error) {
+	url := fmt.Sprintf("%s/spots/details?spotId=%s", r.baseURL, spotId)
+
+	resp, err := r.httpClient.Get(url)
+	if err != nil {
+		return sink.SpotMeta{}, fmt.Errorf("error fetching spot details for %s: %w", spotId, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return sink.SpotMeta{}, fmt.Errorf("spotresolver: unexpected status %d fetching spot details for %s", resp.StatusCode, spotId)
+	}
+
+	var details struct {
+		Spot struct {
+			Name      string  `json:"name"`
+			Lat       float64 `json:"lat"`
+			Lon       float64 `json:"lon"`
+			Timezone  string  `json:"timezone"`
+			Country   string  `json:"country"`
+			Subregion struct {
+				Name string `json:"name"`
+			} `json:"subregion"`
+			Region struct {
+				Name string `json:"name"`
+			} `json:"region"`
+		} `json:"spot"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
+		return sink.SpotMeta{}, fmt.Errorf("error decoding spot details for %s: %w", spotId, err)
+	}
+
+	return sink.SpotMeta{
+		ID:        spotId,
+		Name:      details.Spot.Name,
+		Lat:       details.Spot.Lat,
+		Lon:       details.Spot.Lon,
+		Timezone:  details.Spot.Timezone,
+		Region:    details.Spot.Region.Name,
+		Subregion: details.Spot.Subregion.Name,
+		Country:   details.Spot.Country,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package spotresolver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func newTestResolver(t *testing.T, srv *httptest.Server) *Resolver {
+	t.Helper()
+	r := New(srv.Client(), filepath.Join(t.TempDir(), "spot_cache.json"))
+	r.baseURL = srv.URL
+	return r
+}
+
+func TestResolve_FallsBackToSpotIDOnNon200Status(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"not found"}`)
+	}))
+	defer srv.Close()
+
+	r := newTestResolver(t, srv)
+	meta := r.Resolve("bad-spot-id")
+
+	if meta.ID != "bad-spot-id" || meta.Name != "bad-spot-id" {
+		t.Fatalf("expected spot ID fallback, got %+v", meta)
+	}
+
+	r.mu.RLock()
+	_, cached := r.cache["bad-spot-id"]
+	r.mu.RUnlock()
+	if cached {
+		t.Fatalf("a failed resolve should not be cached for RefreshInterval")
+	}
+}
+
+func TestResolve_CachesASuccessfulResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"spot":{"name":"Pipeline","lat":21.6,"lon":-158.05,"timezone":"Pacific/Honolulu","country":"US","region":{"name":"Hawaii"},"subregion":{"name":"Oahu"}}}`)
+	}))
+	defer srv.Close()
+
+	r := newTestResolver(t, srv)
+	meta := r.Resolve("pipeline")
+
+	if meta.Name != "Pipeline" || meta.Region != "Hawaii" || meta.Subregion != "Oahu" {
+		t.Fatalf("expected resolved metadata, got %+v", meta)
+	}
+
+	r.mu.RLock()
+	_, cached := r.cache["pipeline"]
+	r.mu.RUnlock()
+	if !cached {
+		t.Fatalf("a successful resolve should be cached")
+	}
+}
+
+func TestResolve_FallsBackToCachedValueWhenRefetchFails(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			fmt.Fprint(w, `{"spot":{"name":"Pipeline"}}`)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	r := newTestResolver(t, srv)
+	first := r.Resolve("pipeline")
+
+	r.mu.Lock()
+	entry := r.cache["pipeline"]
+	entry.FetchedAt = entry.FetchedAt.Add(-RefreshInterval * 2)
+	r.cache["pipeline"] = entry
+	r.mu.Unlock()
+
+	second := r.Resolve("pipeline")
+	if second.Name != first.Name {
+		t.Fatalf("expected the stale cached value on refetch failure, got %+v", second)
+	}
+}
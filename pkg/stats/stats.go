@@ -0,0 +1,136 @@
+// Package stats aggregates per-spot, per-forecast-type fetch outcomes so
+// they can be printed at the end of a run, served from /healthz, and
+// scraped as Prometheus metrics.
+package stats
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry records fetch outcomes and exposes them two ways: as a
+// structured in-memory summary (Snapshot, String) and as Prometheus
+// counters (Handler) for scrape-based monitoring.
+type Registry struct {
+	fetchTotal    *prometheus.CounterVec
+	fetchDuration *prometheus.HistogramVec
+	gatherer      prometheus.Gatherer
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	Successes int
+	Errors    int
+	LastError string
+}
+
+// Snapshot is a point-in-time, JSON-friendly view of one spot/forecast
+// type's fetch outcomes.
+type Snapshot struct {
+	SpotID       string `json:"spotId"`
+	ForecastType string `json:"forecastType"`
+	Successes    int    `json:"successes"`
+	Errors       int    `json:"errors"`
+	LastError    string `json:"lastError,omitempty"`
+}
+
+// NewRegistry returns a Registry backed by its own Prometheus registry, so
+// /metrics only ever exposes this project's own counters.
+func NewRegistry() *Registry {
+	reg := prometheus.NewRegistry()
+
+	r := &Registry{
+		fetchTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "surfline_fetch_total",
+			Help: "Count of forecast fetch attempts, by spot, forecast type, and result.",
+		}, []string{"spot", "type", "result"}),
+		fetchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "surfline_fetch_duration_seconds",
+			Help: "Time spent fetching and writing a single forecast, by spot and forecast type.",
+		}, []string{"spot", "type"}),
+		gatherer: reg,
+		entries:  map[string]*entry{},
+	}
+
+	reg.MustRegister(r.fetchTotal, r.fetchDuration)
+	return r
+}
+
+func key(spotID, forecastType string) string { return spotID + "/" + forecastType }
+
+// Record logs the outcome of one fetch attempt, updating both the
+// Prometheus counters and the in-memory summary served from /healthz.
+func (r *Registry) Record(spotID, forecastType string, err error, duration time.Duration) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	r.fetchTotal.WithLabelValues(spotID, forecastType, result).Inc()
+	r.fetchDuration.WithLabelValues(spotID, forecastType).Observe(duration.Seconds())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.entries[key(spotID, forecastType)]
+	if !ok {
+		e = &entry{}
+		r.entries[key(spotID, forecastType)] = e
+	}
+	if err != nil {
+		e.Errors++
+		e.LastError = err.Error()
+	} else {
+		e.Successes++
+	}
+}
+
+// Snapshot returns the current per-spot/per-forecast-type fetch summary.
+func (r *Registry) Snapshot() []Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshots := make([]Snapshot, 0, len(r.entries))
+	for k, e := range r.entries {
+		spotID, forecastType, _ := strings.Cut(k, "/")
+		snapshots = append(snapshots, Snapshot{
+			SpotID:       spotID,
+			ForecastType: forecastType,
+			Successes:    e.Successes,
+			Errors:       e.Errors,
+			LastError:    e.LastError,
+		})
+	}
+	return snapshots
+}
+
+// Handler serves the registry's counters in the Prometheus exposition
+// format, for mounting at /metrics.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.gatherer, promhttp.HandlerOpts{})
+}
+
+// String renders a one-line-per-entry summary, for the end-of-run log line.
+func (r *Registry) String() string {
+	snapshots := r.Snapshot()
+	if len(snapshots) == 0 {
+		return "no fetches recorded"
+	}
+
+	var b strings.Builder
+	for _, s := range snapshots {
+		fmt.Fprintf(&b, "%s/%s: %d ok, %d failed", s.SpotID, s.ForecastType, s.Successes, s.Errors)
+		if s.LastError != "" {
+			fmt.Fprintf(&b, " (last error: %s)", s.LastError)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,32 @@
+package stats
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecord_AggregatesSuccessesAndErrorsPerSpotAndType(t *testing.T) {
+	r := NewRegistry()
+	r.Record("pipeline", "wave", nil, time.Millisecond)
+	r.Record("pipeline", "wave", errors.New("timed out"), time.Millisecond)
+	r.Record("pipeline", "wind", nil, time.Millisecond)
+
+	snapshots := map[string]Snapshot{}
+	for _, s := range r.Snapshot() {
+		snapshots[key(s.SpotID, s.ForecastType)] = s
+	}
+
+	wave, ok := snapshots[key("pipeline", "wave")]
+	if !ok {
+		t.Fatalf("expected a pipeline/wave entry, got %v", snapshots)
+	}
+	if wave.Successes != 1 || wave.Errors != 1 || wave.LastError != "timed out" {
+		t.Fatalf("expected 1 success, 1 error, last error recorded, got %+v", wave)
+	}
+
+	wind, ok := snapshots[key("pipeline", "wind")]
+	if !ok || wind.Successes != 1 || wind.Errors != 0 {
+		t.Fatalf("expected a clean pipeline/wind entry, got %+v", wind)
+	}
+}